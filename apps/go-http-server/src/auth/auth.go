@@ -0,0 +1,215 @@
+// Package auth issues and validates JWTs for the HTTP server and exposes the
+// middleware/handlers needed to turn routes into per-user, role-gated ones.
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Principal identifies the authenticated caller attached to the request context.
+type Principal struct {
+	Subject string   `json:"sub"`
+	Roles   []string `json:"roles"`
+}
+
+// HasRole reports whether the principal was granted role.
+func (p Principal) HasRole(role string) bool {
+	for _, have := range p.Roles {
+		if have == role {
+			return true
+		}
+	}
+	return false
+}
+
+type ctxKey int
+
+const principalKey ctxKey = 0
+
+// Credentials is the JSON payload expected by LoginHandler.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// UserRecord is what a UserDB returns for a valid login. PasswordHash is a
+// bcrypt hash, never the plaintext password — see HashPassword.
+type UserRecord struct {
+	PasswordHash string
+	Roles        []string
+}
+
+// UserDB looks up credentials for LoginHandler. Callers supply their own
+// implementation backed by whatever store they use for accounts.
+type UserDB interface {
+	Lookup(username string) (UserRecord, bool)
+}
+
+// HashPassword bcrypt-hashes password for storage in a UserRecord.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("auth: hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+type claims struct {
+	Roles []string `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+// IssueToken signs an HS256 token for subject/roles valid for ttl.
+func IssueToken(secret []byte, subject string, roles []string, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, newClaims(subject, roles, ttl))
+	return token.SignedString(secret)
+}
+
+// IssueTokenRS256 signs a token with an RSA private key, for deployments that
+// want verifiers to hold only a public key rather than a shared secret.
+func IssueTokenRS256(key *rsa.PrivateKey, subject string, roles []string, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, newClaims(subject, roles, ttl))
+	return token.SignedString(key)
+}
+
+func newClaims(subject string, roles []string, ttl time.Duration) claims {
+	now := time.Now()
+	return claims{
+		Roles: roles,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+}
+
+// JWTAuthMiddleware validates the bearer token against secret (HS256) and
+// populates the request context with the resolved Principal.
+func JWTAuthMiddleware(secret []byte) func(http.Handler) http.Handler {
+	return verifyingMiddleware(func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("auth: unexpected signing method")
+		}
+		return secret, nil
+	})
+}
+
+// JWTAuthMiddlewareRS256 is JWTAuthMiddleware for tokens signed with RS256,
+// verified against the corresponding public key.
+func JWTAuthMiddlewareRS256(pub *rsa.PublicKey) func(http.Handler) http.Handler {
+	return verifyingMiddleware(func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, errors.New("auth: unexpected signing method")
+		}
+		return pub, nil
+	})
+}
+
+func verifyingMiddleware(keyFunc jwt.Keyfunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenString, err := bearerToken(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			principal, err := validate(tokenString, keyFunc)
+			if err != nil {
+				http.Error(w, "invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey, principal)))
+		})
+	}
+}
+
+func validate(tokenString string, keyFunc jwt.Keyfunc) (Principal, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &claims{}, keyFunc)
+	if err != nil || !token.Valid {
+		return Principal{}, errors.New("auth: invalid or expired token")
+	}
+	c := token.Claims.(*claims)
+	return Principal{Subject: c.Subject, Roles: c.Roles}, nil
+}
+
+// ValidateToken validates an HS256 token against secret and returns the
+// Principal it carries. Unlike JWTAuthMiddleware, it takes the token string
+// directly rather than reading it from the Authorization header, for
+// callers that receive a token some other way (e.g. a WebSocket handshake's
+// query string, which browsers cannot attach custom headers to).
+func ValidateToken(secret []byte, tokenString string) (Principal, error) {
+	return validate(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("auth: unexpected signing method")
+		}
+		return secret, nil
+	})
+}
+
+func bearerToken(r *http.Request) (string, error) {
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, "Bearer ") {
+		return "", errors.New("missing bearer token")
+	}
+	return strings.TrimPrefix(h, "Bearer "), nil
+}
+
+// FromContext returns the Principal attached by the auth middleware, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey).(Principal)
+	return p, ok
+}
+
+// RequireRole wraps next so it only runs for principals granted role.
+func RequireRole(role string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := FromContext(r.Context())
+		if !ok {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !principal.HasRole(role) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LoginHandler authenticates against userDB and returns a signed HS256 token.
+func LoginHandler(secret []byte, userDB UserDB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var creds Credentials
+		if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		record, ok := userDB.Lookup(creds.Username)
+		if !ok || bcrypt.CompareHashAndPassword([]byte(record.PasswordHash), []byte(creds.Password)) != nil {
+			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+			return
+		}
+
+		token, err := IssueToken(secret, creds.Username, record.Roles, time.Hour)
+		if err != nil {
+			http.Error(w, "failed to issue token", http.StatusInternalServerError)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"token": token})
+	}
+}
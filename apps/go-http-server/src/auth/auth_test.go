@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type fakeUserDB map[string]UserRecord
+
+func (f fakeUserDB) Lookup(username string) (UserRecord, bool) {
+	rec, ok := f[username]
+	return rec, ok
+}
+
+func TestIssueAndValidateToken(t *testing.T) {
+	secret := []byte("test-secret")
+
+	token, err := IssueToken(secret, "alice", []string{"admin"}, time.Minute)
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+
+	var principal Principal
+	handler := JWTAuthMiddleware(secret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if principal.Subject != "alice" || !principal.HasRole("admin") {
+		t.Fatalf("principal = %+v, want subject alice with role admin", principal)
+	}
+}
+
+func TestJWTAuthMiddlewareRejectsExpiredToken(t *testing.T) {
+	secret := []byte("test-secret")
+	token, err := IssueToken(secret, "alice", nil, -time.Minute)
+	if err != nil {
+		t.Fatalf("IssueToken() error = %v", err)
+	}
+
+	handler := JWTAuthMiddleware(secret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not run for an expired token")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestJWTAuthMiddlewareRejectsMissingOrTamperedToken(t *testing.T) {
+	secret := []byte("test-secret")
+	handler := JWTAuthMiddleware(secret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not run without a valid token")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("missing token: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	token, _ := IssueToken(secret, "alice", nil, time.Minute)
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token+"tampered")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("tampered token: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	secret := []byte("test-secret")
+	guarded := RequireRole("admin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	handler := JWTAuthMiddleware(secret)(guarded)
+
+	memberToken, _ := IssueToken(secret, "bob", []string{"member"}, time.Minute)
+	req := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+	req.Header.Set("Authorization", "Bearer "+memberToken)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("member: status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	adminToken, _ := IssueToken(secret, "carol", []string{"admin"}, time.Minute)
+	req = httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("admin: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestLoginHandler(t *testing.T) {
+	secret := []byte("test-secret")
+	hash, err := HashPassword("hunter2")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	userDB := fakeUserDB{
+		"alice": {PasswordHash: hash, Roles: []string{"admin"}},
+	}
+	handler := LoginHandler(secret, userDB)
+
+	req := httptest.NewRequest(http.MethodPost, "/login", jsonBody(`{"username":"alice","password":"wrong"}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/login", jsonBody(`{"username":"alice","password":"hunter2"}`))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("correct password: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestHashPasswordRoundTrips(t *testing.T) {
+	hash, err := HashPassword("hunter2")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	if hash == "hunter2" {
+		t.Fatal("HashPassword() returned the plaintext password unchanged")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("hunter2")); err != nil {
+		t.Fatalf("hash does not verify against the original password: %v", err)
+	}
+}
+
+func jsonBody(s string) *strings.Reader {
+	return strings.NewReader(s)
+}
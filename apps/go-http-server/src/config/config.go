@@ -0,0 +1,69 @@
+// Package config loads the server's typed configuration from the
+// environment and, optionally, watches a YAML file for live updates to the
+// subset of fields that are safe to change without a restart.
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// Config is the full set of settings the server needs at startup, bound from
+// environment variables prefixed APP_ (e.g. APP_HOST_PORT).
+type Config struct {
+	HostPort       string        `envconfig:"HOST_PORT" default:"8080"`
+	ReadTimeout    time.Duration `envconfig:"READ_TIMEOUT" default:"1s"`
+	WriteTimeout   time.Duration `envconfig:"WRITE_TIMEOUT" default:"10s"`
+	ShutdownGrace  time.Duration `envconfig:"SHUTDOWN_GRACE" default:"15s"`
+	StorageBackend string        `envconfig:"STORAGE_BACKEND" default:"memory"`
+	JWTSecret      string        `envconfig:"JWT_SECRET"`
+	OTelEndpoint   string        `envconfig:"OTEL_ENDPOINT"`
+	LogLevel       string        `envconfig:"LOG_LEVEL" default:"info"`
+	WatchConfig    bool          `envconfig:"WATCH_CONFIG" default:"false"`
+	ConfigFile     string        `envconfig:"CONFIG_FILE" default:"./config.yaml"`
+}
+
+var validLogLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+// Load binds Config from the environment (prefix APP_) and validates it.
+func Load() (*Config, error) {
+	var cfg Config
+	if err := envconfig.Process("APP", &cfg); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate reports the first configuration error found, with a message
+// naming the offending field so a misconfigured deployment fails fast.
+func (c Config) Validate() error {
+	if c.HostPort == "" {
+		return fmt.Errorf("config: APP_HOST_PORT must not be empty")
+	}
+	if c.ReadTimeout <= 0 {
+		return fmt.Errorf("config: APP_READ_TIMEOUT must be positive, got %s", c.ReadTimeout)
+	}
+	if c.WriteTimeout <= 0 {
+		return fmt.Errorf("config: APP_WRITE_TIMEOUT must be positive, got %s", c.WriteTimeout)
+	}
+	if c.ShutdownGrace < 0 {
+		return fmt.Errorf("config: APP_SHUTDOWN_GRACE must not be negative, got %s", c.ShutdownGrace)
+	}
+	switch c.StorageBackend {
+	case "memory", "badger", "postgres":
+	default:
+		return fmt.Errorf("config: APP_STORAGE_BACKEND must be one of memory|badger|postgres, got %q", c.StorageBackend)
+	}
+	if !validLogLevels[c.LogLevel] {
+		return fmt.Errorf("config: APP_LOG_LEVEL must be one of debug|info|warn|error, got %q", c.LogLevel)
+	}
+	if c.WatchConfig && c.ConfigFile == "" {
+		return fmt.Errorf("config: APP_CONFIG_FILE must be set when APP_WATCH_CONFIG=true")
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func validConfig() Config {
+	return Config{
+		HostPort:       "8080",
+		ReadTimeout:    time.Second,
+		WriteTimeout:   10 * time.Second,
+		ShutdownGrace:  15 * time.Second,
+		StorageBackend: "memory",
+		LogLevel:       "info",
+	}
+}
+
+func TestValidateAcceptsValidConfig(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+}
+
+func TestValidateRejectsBadFields(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(*Config)
+	}{
+		{"empty host port", func(c *Config) { c.HostPort = "" }},
+		{"non-positive read timeout", func(c *Config) { c.ReadTimeout = 0 }},
+		{"non-positive write timeout", func(c *Config) { c.WriteTimeout = 0 }},
+		{"negative shutdown grace", func(c *Config) { c.ShutdownGrace = -time.Second }},
+		{"unknown storage backend", func(c *Config) { c.StorageBackend = "sqlite" }},
+		{"unknown log level", func(c *Config) { c.LogLevel = "verbose" }},
+		{"watch without config file", func(c *Config) { c.WatchConfig = true; c.ConfigFile = "" }},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validConfig()
+			tc.mutate(&cfg)
+			if err := cfg.Validate(); err == nil {
+				t.Fatalf("Validate() error = nil, want an error for %s", tc.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+)
+
+// liveOverlay is the subset of Config that can change without a restart:
+// log level and the timeouts applied to new connections.
+type liveOverlay struct {
+	LogLevel     string        `yaml:"log_level"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+}
+
+// Watcher holds the live Config behind an atomic.Value so handlers can read
+// it without locking, while a background goroutine swaps in updates parsed
+// from the watched YAML file.
+type Watcher struct {
+	value    atomic.Value // *Config
+	path     string
+	OnReload func(*Config)
+}
+
+// NewWatcher returns a Watcher seeded with initial, watching path for updates.
+func NewWatcher(initial *Config, path string) *Watcher {
+	w := &Watcher{path: path}
+	w.value.Store(initial)
+	return w
+}
+
+// Current returns the most recently loaded Config.
+func (w *Watcher) Current() *Config {
+	return w.value.Load().(*Config)
+}
+
+// Watch starts watching the config file for changes until ctx is done. It
+// returns once the watcher is established; reloads happen in the background.
+func (w *Watcher) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("config: start file watcher: %w", err)
+	}
+	if err := watcher.Add(w.path); err != nil {
+		watcher.Close()
+		return fmt.Errorf("config: watch %s: %w", w.path, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					w.reload()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				fmt.Printf("config: watcher error: %s\n", err.Error())
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (w *Watcher) reload() {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		fmt.Printf("config: failed to read %s: %s\n", w.path, err.Error())
+		return
+	}
+
+	var overlay liveOverlay
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		fmt.Printf("config: failed to parse %s: %s\n", w.path, err.Error())
+		return
+	}
+
+	current := w.Current()
+	updated := *current
+	if overlay.LogLevel != "" {
+		updated.LogLevel = overlay.LogLevel
+	}
+	if overlay.ReadTimeout > 0 {
+		updated.ReadTimeout = overlay.ReadTimeout
+	}
+	if overlay.WriteTimeout > 0 {
+		updated.WriteTimeout = overlay.WriteTimeout
+	}
+
+	if err := updated.Validate(); err != nil {
+		fmt.Printf("config: rejecting live reload: %s\n", err.Error())
+		return
+	}
+
+	w.value.Store(&updated)
+	fmt.Printf("config: reloaded from %s\n", w.path)
+	if w.OnReload != nil {
+		w.OnReload(&updated)
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeOverlay(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write overlay: %v", err)
+	}
+}
+
+func TestWatcherReloadAppliesOverlay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeOverlay(t, path, "log_level: debug\nread_timeout: 2s\n")
+
+	initial := validConfig()
+	w := NewWatcher(&initial, path)
+
+	var reloaded *Config
+	w.OnReload = func(updated *Config) { reloaded = updated }
+
+	w.reload()
+
+	current := w.Current()
+	if current.LogLevel != "debug" {
+		t.Fatalf("LogLevel = %q, want debug", current.LogLevel)
+	}
+	if current.ReadTimeout != 2*time.Second {
+		t.Fatalf("ReadTimeout = %s, want 2s", current.ReadTimeout)
+	}
+	if current.WriteTimeout != initial.WriteTimeout {
+		t.Fatalf("WriteTimeout = %s, want unchanged %s", current.WriteTimeout, initial.WriteTimeout)
+	}
+	if reloaded == nil || reloaded.LogLevel != "debug" {
+		t.Fatalf("OnReload was not called with the updated config")
+	}
+}
+
+func TestWatcherReloadRejectsInvalidOverlay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeOverlay(t, path, "log_level: verbose\n")
+
+	initial := validConfig()
+	w := NewWatcher(&initial, path)
+
+	called := false
+	w.OnReload = func(*Config) { called = true }
+
+	w.reload()
+
+	if w.Current().LogLevel != initial.LogLevel {
+		t.Fatalf("LogLevel = %q, want unchanged %q after a rejected reload", w.Current().LogLevel, initial.LogLevel)
+	}
+	if called {
+		t.Fatal("OnReload should not run when the reloaded config fails validation")
+	}
+}
+
+func TestWatcherCurrentIsSafeForConcurrentReaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeOverlay(t, path, "log_level: debug\n")
+
+	initial := validConfig()
+	w := NewWatcher(&initial, path)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			w.reload()
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		_ = w.Current().LogLevel
+	}
+	<-done
+}
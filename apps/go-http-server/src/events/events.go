@@ -0,0 +1,109 @@
+// Package events fans out user change events to WebSocket subscribers so
+// dashboards can react to CRUD mutations without polling.
+package events
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Event is the JSON payload streamed to subscribers.
+type Event struct {
+	Type      string `json:"type"`
+	RequestID string `json:"request_id,omitempty"`
+	Payload   any    `json:"payload"`
+}
+
+const (
+	// TypeUserCreated is emitted after a user record is created.
+	TypeUserCreated = "user.created"
+	// TypeUserDeleted is emitted after a user record is deleted.
+	TypeUserDeleted = "user.deleted"
+)
+
+var (
+	activeConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ws_active_connections",
+		Help: "Number of currently subscribed WebSocket clients.",
+	})
+	droppedMessages = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "ws_dropped_messages_total",
+		Help: "Number of events dropped because a subscriber's buffer was full.",
+	})
+)
+
+// Broker fans out published events to any number of subscribers. Each
+// subscriber gets its own buffered channel; a subscriber that falls behind
+// has messages dropped rather than blocking the publisher, and is
+// disconnected once it has dropped maxDropped messages in a row.
+type Broker struct {
+	mtx         sync.Mutex
+	subscribers map[chan Event]*subscriberState
+	bufferSize  int
+	maxDropped  int
+}
+
+type subscriberState struct {
+	dropped int
+}
+
+// NewBroker returns a Broker whose subscriber channels are buffered to
+// bufferSize, disconnecting a subscriber after maxDropped consecutive
+// dropped messages.
+func NewBroker(bufferSize, maxDropped int) *Broker {
+	return &Broker{
+		subscribers: make(map[chan Event]*subscriberState),
+		bufferSize:  bufferSize,
+		maxDropped:  maxDropped,
+	}
+}
+
+// Publish builds an Event from the given fields and fans it out to every
+// current subscriber.
+func (b *Broker) Publish(eventType, requestID string, payload any) {
+	event := Event{Type: eventType, RequestID: requestID, Payload: payload}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	for ch, state := range b.subscribers {
+		select {
+		case ch <- event:
+			state.dropped = 0
+		default:
+			state.dropped++
+			droppedMessages.Inc()
+			if state.dropped >= b.maxDropped {
+				delete(b.subscribers, ch)
+				close(ch)
+				activeConnections.Dec()
+			}
+		}
+	}
+}
+
+// Subscribe registers a new subscriber and returns the channel it should
+// read events from. Call Unsubscribe when done to release it.
+func (b *Broker) Subscribe() chan Event {
+	ch := make(chan Event, b.bufferSize)
+
+	b.mtx.Lock()
+	b.subscribers[ch] = &subscriberState{}
+	b.mtx.Unlock()
+
+	activeConnections.Inc()
+	return ch
+}
+
+// Unsubscribe removes ch from the broker and closes it. It is a no-op if ch
+// was already removed (e.g. by a slow-consumer disconnect).
+func (b *Broker) Unsubscribe(ch chan Event) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+		activeConnections.Dec()
+	}
+}
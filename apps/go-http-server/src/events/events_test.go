@@ -0,0 +1,72 @@
+package events
+
+import "testing"
+
+func TestBrokerFansOutToAllSubscribers(t *testing.T) {
+	b := NewBroker(4, 3)
+	a := b.Subscribe()
+	c := b.Subscribe()
+
+	b.Publish(TypeUserCreated, "req-1", map[string]int{"id": 1})
+
+	for _, ch := range []chan Event{a, c} {
+		select {
+		case got := <-ch:
+			if got.Type != TypeUserCreated || got.RequestID != "req-1" {
+				t.Fatalf("got = %+v, want type %s request_id req-1", got, TypeUserCreated)
+			}
+		default:
+			t.Fatal("expected a subscriber to receive the published event")
+		}
+	}
+}
+
+func TestBrokerDropsWhenSubscriberBufferIsFull(t *testing.T) {
+	b := NewBroker(1, 3)
+	sub := b.Subscribe()
+
+	b.Publish(TypeUserCreated, "req-1", nil)
+	b.Publish(TypeUserCreated, "req-2", nil)
+
+	got := <-sub
+	if got.RequestID != "req-1" {
+		t.Fatalf("request_id = %s, want req-1 (req-2 should have been dropped)", got.RequestID)
+	}
+}
+
+func TestBrokerDisconnectsAfterMaxDropped(t *testing.T) {
+	b := NewBroker(1, 2)
+	sub := b.Subscribe()
+
+	b.Publish(TypeUserCreated, "fills-buffer", nil)
+	b.Publish(TypeUserCreated, "dropped-1", nil)
+	b.Publish(TypeUserCreated, "dropped-2", nil)
+
+	b.mtx.Lock()
+	_, stillSubscribed := b.subscribers[sub]
+	b.mtx.Unlock()
+	if stillSubscribed {
+		t.Fatal("subscriber should have been disconnected after maxDropped consecutive drops")
+	}
+
+	// The event buffered before the disconnect is still delivered; the
+	// channel reports closed after it.
+	if got := <-sub; got.RequestID != "fills-buffer" {
+		t.Fatalf("request_id = %s, want fills-buffer (buffered before the disconnect)", got.RequestID)
+	}
+	if _, ok := <-sub; ok {
+		t.Fatal("channel should be closed once the subscriber is disconnected")
+	}
+}
+
+func TestUnsubscribeIsIdempotent(t *testing.T) {
+	b := NewBroker(1, 3)
+	sub := b.Subscribe()
+
+	b.Unsubscribe(sub)
+	b.Unsubscribe(sub)
+
+	if _, ok := <-sub; ok {
+		t.Fatal("channel should be closed after Unsubscribe")
+	}
+}
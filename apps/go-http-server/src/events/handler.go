@@ -0,0 +1,79 @@
+package events
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const heartbeatInterval = 30 * time.Second
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// Authenticate validates the token and returns an error if it is missing or
+// invalid. Handler calls this with the `token` query parameter, since
+// browser WebSocket clients cannot set an Authorization header on the
+// handshake request.
+type Authenticate func(token string) error
+
+// Handler upgrades the request to a WebSocket and streams every event
+// published to broker as JSON until the client disconnects. The handshake
+// must carry a valid token in the `token` query parameter, checked via
+// authenticate. A ping is sent every 30s, and a read pump discards incoming
+// frames so client-initiated closes and pongs are processed promptly.
+func Handler(broker *Broker, authenticate Authenticate) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := authenticate(r.URL.Query().Get("token")); err != nil {
+			http.Error(w, "invalid or expired token", http.StatusUnauthorized)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer conn.Close()
+
+		sub := broker.Subscribe()
+		defer broker.Unsubscribe(sub)
+
+		// gorilla/websocket needs an active reader to process control frames
+		// (client close, pong replies); without one a clean client
+		// disconnect is never noticed.
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case event, ok := <-sub:
+				if !ok {
+					return
+				}
+				if err := conn.WriteJSON(event); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package lifecycle tracks process readiness through a small state machine
+// and exposes the /healthz and /readiness handlers that probes poll.
+package lifecycle
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// State is a stage in the process lifecycle.
+type State int32
+
+const (
+	// StateStarting is the default state before the server accepts traffic.
+	StateStarting State = iota
+	// StateReady means the server is accepting and serving traffic.
+	StateReady
+	// StateDraining means the server has stopped advertising readiness and
+	// is waiting for in-flight requests and load balancers to notice.
+	StateDraining
+	// StateStopped means the server has shut down.
+	StateStopped
+)
+
+func (s State) String() string {
+	switch s {
+	case StateStarting:
+		return "starting"
+	case StateReady:
+		return "ready"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// Manager holds the current lifecycle state, safe for concurrent use by the
+// goroutine driving shutdown and the handlers serving probes.
+type Manager struct {
+	state atomic.Int32
+}
+
+// NewManager returns a Manager starting in StateStarting.
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Set transitions the manager to state.
+func (m *Manager) Set(state State) {
+	m.state.Store(int32(state))
+}
+
+// Get returns the current state.
+func (m *Manager) Get() State {
+	return State(m.state.Load())
+}
+
+// Healthz reports liveness: it only fails once the process has stopped,
+// so a container orchestrator won't restart a pod that is merely draining.
+func (m *Manager) Healthz(w http.ResponseWriter, r *http.Request) {
+	if m.Get() == StateStopped {
+		http.Error(w, StateStopped.String(), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Readiness reports whether the server should receive new traffic.
+func (m *Manager) Readiness(w http.ResponseWriter, r *http.Request) {
+	state := m.Get()
+	if state != StateReady {
+		http.Error(w, state.String(), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
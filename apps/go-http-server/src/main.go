@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,67 +14,156 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
-	"sync"
 	"time"
 
+	"github.com/anggerdeni/homelab/apps/go-http-server/src/auth"
+	"github.com/anggerdeni/homelab/apps/go-http-server/src/config"
+	"github.com/anggerdeni/homelab/apps/go-http-server/src/events"
+	"github.com/anggerdeni/homelab/apps/go-http-server/src/lifecycle"
+	"github.com/anggerdeni/homelab/apps/go-http-server/src/storage"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// User represents a simple user model in our application
-type User struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
-}
+// store is the active UserStore backend, selected at startup via
+// APP_STORAGE_BACKEND (see config.Config and storage.NewStore).
+var store storage.UserStore
 
-// Repo acts as a simple in-memory database
-type Repo struct {
-	mtx    sync.RWMutex
-	users  map[int]User
-	nextID int
-}
+var (
+	meter                = otel.Meter("go-http-server")
+	registeredUsersCount metric.Int64Counter
+	requestDuration      metric.Float64Histogram
+	activeRequests       metric.Int64UpDownCounter
+	requestCount         metric.Int64Counter
+)
+
+// initMetrics creates the OTel instruments used across the server. It must
+// run once at startup, before any handler runs, so requests never race the
+// creation of a counter.
+func initMetrics() error {
+	var err error
 
-func NewRepo() *Repo {
-	return &Repo{
-		users:  make(map[int]User),
-		nextID: 1,
+	registeredUsersCount, err = meter.Int64Counter(
+		"registeredUsers.count",
+		metric.WithDescription("Number of registered users."),
+		metric.WithUnit("{call}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	requestDuration, err = meter.Float64Histogram(
+		"http.server.request.duration",
+		metric.WithDescription("Duration of HTTP requests."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return err
 	}
+
+	activeRequests, err = meter.Int64UpDownCounter(
+		"http.server.active_requests",
+		metric.WithDescription("Number of in-flight HTTP requests."),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	requestCount, err = meter.Int64Counter(
+		"http.server.requests",
+		metric.WithDescription("Number of HTTP requests."),
+		metric.WithUnit("{request}"),
+	)
+	return err
 }
 
-func (r *Repo) Get() (map[int]User, error) {
-	r.mtx.RLock()
-	defer r.mtx.RUnlock()
-	return r.users, nil
+// statusRecorder captures the status code a handler writes so middleware can
+// report it after the fact.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
 }
 
-func (r *Repo) Find(id int) (User, bool) {
-	r.mtx.RLock()
-	defer r.mtx.RUnlock()
-	user, ok := r.users[id]
-	return user, ok
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
 }
 
-func (r *Repo) Save(user User) User {
-	r.mtx.Lock()
-	defer r.mtx.Unlock()
-	user.ID = r.nextID
-	r.users[user.ID] = user
-	r.nextID++
-	return user
+// Hijack passes through to the wrapped writer so the WebSocket upgrade on
+// /events can take over the underlying connection.
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(rec.ResponseWriter).Hijack()
 }
 
-func (r *Repo) Delete(id int) {
-	r.mtx.Lock()
-	defer r.mtx.Unlock()
-	delete(r.users, id)
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetPathTemplate(); err == nil {
+			return tmpl
+		}
+	}
+	return r.URL.Path
 }
 
-var repo = NewRepo()
+// TracingMiddleware wraps next in a single otelhttp-managed span per request,
+// named after the matched route template, and propagates W3C traceparent
+// headers. It records the outcome on that same span rather than starting a
+// second one, so each request produces exactly one span.
+func TracingMiddleware(next http.Handler) http.Handler {
+	instrumented := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(attribute.String("http.route", route))
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		span.SetAttributes(attribute.Int("http.status_code", rec.status))
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	})
+	return otelhttp.NewHandler(instrumented, "go-http-server", otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return routeTemplate(r)
+	}))
+}
+
+// MetricsMiddleware emits the RED signals (rate, errors, duration) for every
+// route as OTel instruments.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+		attrs := metric.WithAttributes(
+			attribute.String("http.route", route),
+			attribute.String("http.method", r.Method),
+		)
+
+		activeRequests.Add(r.Context(), 1, attrs)
+		defer activeRequests.Add(r.Context(), -1, attrs)
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		resultAttrs := metric.WithAttributes(
+			attribute.String("http.route", route),
+			attribute.String("http.method", r.Method),
+			attribute.Int("http.status_code", rec.status),
+		)
+		requestDuration.Record(r.Context(), time.Since(start).Seconds(), resultAttrs)
+		requestCount.Add(r.Context(), 1, resultAttrs)
+	})
+}
 
 func ListUserHandler(w http.ResponseWriter, r *http.Request) {
-	users, err := repo.Get()
+	principal, _ := auth.FromContext(r.Context())
+	users, err := store.List(r.Context(), principal.Subject)
 	if err != nil {
 		http.Error(w, "failed to get users", http.StatusInternalServerError)
 		return
@@ -86,35 +178,35 @@ func GetUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, ok := repo.Find(id)
-	if !ok {
+	principal, _ := auth.FromContext(r.Context())
+	user, err := store.Get(r.Context(), principal.Subject, id)
+	if errors.Is(err, storage.ErrNotFound) {
 		http.Error(w, "User not found", http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		http.Error(w, "failed to get user", http.StatusInternalServerError)
+		return
+	}
 
 	json.NewEncoder(w).Encode(user)
 }
 
 func CreateUserHandler(w http.ResponseWriter, r *http.Request) {
-	var user User
+	var user storage.User
 	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	user = repo.Save(user)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-
-	var meter = otel.Meter("go-http-server-e")
-	registeredUsersCount, err := meter.Int64Counter(
-		"registeredUsers.count",
-		metric.WithDescription("Number of registered users."),
-		metric.WithUnit("{call}"),
-	)
+	principal, _ := auth.FromContext(r.Context())
+	user, err := store.Create(r.Context(), principal.Subject, user)
 	if err != nil {
-		fmt.Printf("error otel: %s", err.Error())
+		http.Error(w, "failed to create user", http.StatusInternalServerError)
+		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
 
 	registeredUsersCount.Add(r.Context(), 1)
 
@@ -128,10 +220,48 @@ func DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	repo.Delete(id)
+	principal, _ := auth.FromContext(r.Context())
+	if err := store.Delete(r.Context(), principal.Subject, id); errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "failed to delete user", http.StatusInternalServerError)
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// staticUserDB is a placeholder account store for the login endpoint until a
+// persistent UserStore backs it.
+type staticUserDB map[string]auth.UserRecord
+
+func (db staticUserDB) Lookup(username string) (auth.UserRecord, bool) {
+	rec, ok := db[username]
+	return rec, ok
+}
+
+// RequestIDMiddleware assigns each request a correlation ID (reusing one
+// supplied via X-Request-Id), echoes it back in the response, and stashes it
+// in the context so mutations can propagate it into the events they emit.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-Id")
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set("X-Request-Id", requestID)
+		next.ServeHTTP(w, r.WithContext(storage.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
 func ContentTypeMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		accept := r.Header.Get("Accept")
@@ -145,6 +275,28 @@ func ContentTypeMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// TimeoutMiddleware applies the read/write deadlines from the live config to
+// each request via http.ResponseController. Reloads take effect through
+// cfgWatcher's existing atomic.Value (see config.Watcher), rather than by
+// writing srv.ReadTimeout/WriteTimeout on the running *http.Server: net/http
+// reads those fields directly and unsynchronized, so mutating them from the
+// fsnotify goroutine while requests are in flight is a data race.
+func TimeoutMiddleware(cfgWatcher *config.Watcher) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			live := cfgWatcher.Current()
+			rc := http.NewResponseController(w)
+			if live.ReadTimeout > 0 {
+				rc.SetReadDeadline(time.Now().Add(live.ReadTimeout))
+			}
+			if live.WriteTimeout > 0 {
+				rc.SetWriteDeadline(time.Now().Add(live.WriteTimeout))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func main() {
 	// Handle SIGINT (CTRL+C) gracefully.
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
@@ -160,31 +312,133 @@ func main() {
 		err = errors.Join(err, otelShutdown(context.Background()))
 	}()
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	if err := initMetrics(); err != nil {
+		log.Fatal(err.Error())
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	cfgWatcher := config.NewWatcher(cfg, cfg.ConfigFile)
+	if cfg.WatchConfig {
+		if err := cfgWatcher.Watch(ctx); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	jwtSecret := []byte(cfg.JWTSecret)
+	if len(jwtSecret) == 0 {
+		jwtSecret = []byte("dev-secret-change-me")
+		fmt.Println("warning: APP_JWT_SECRET not set, using insecure development secret")
 	}
+	adminPasswordHash, err := auth.HashPassword(os.Getenv("ADMIN_PASSWORD"))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	userDB := staticUserDB{
+		"admin": {PasswordHash: adminPasswordHash, Roles: []string{"admin"}},
+	}
+
+	store, err = storage.NewStore(ctx, cfg.StorageBackend, os.Getenv("BADGER_DIR"), os.Getenv("POSTGRES_DSN"))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if closer, ok := store.(storage.Closer); ok {
+		defer closer.Close()
+	}
+	// Smooth upgrades from the default in-memory backend: if the operator
+	// points MIGRATE_TO_BADGER_DIR at a Badger directory, dump the in-memory
+	// contents there on shutdown so the next start can run with
+	// APP_STORAGE_BACKEND=badger and BADGER_DIR set to that same directory.
+	if memStore, ok := store.(*storage.MemoryStore); ok {
+		if badgerDir := os.Getenv("MIGRATE_TO_BADGER_DIR"); badgerDir != "" {
+			defer func() {
+				badgerStore, err := storage.NewBadgerStore(badgerDir)
+				if err != nil {
+					fmt.Printf("migration: failed to open badger store: %s\n", err.Error())
+					return
+				}
+				defer badgerStore.Close()
+				if err := badgerStore.MigrateFromMemory(memStore); err != nil {
+					fmt.Printf("migration: failed to migrate to badger: %s\n", err.Error())
+				}
+			}()
+		}
+	}
+
+	lc := lifecycle.NewManager()
+
+	broker := events.NewBroker(16, 5)
+	store = storage.NewEventingStore(store, broker)
 
 	router := mux.NewRouter()
+	router.Use(TracingMiddleware)
+	router.Use(MetricsMiddleware)
+	router.Use(RequestIDMiddleware)
 	router.Use(ContentTypeMiddleware)
-	router.Handle("/metrics", promhttp.Handler())
-	router.HandleFunc("/users", ListUserHandler).Methods("GET")
-	router.HandleFunc("/users/{id:[0-9]+}", GetUserHandler).Methods("GET")
-	router.HandleFunc("/users", CreateUserHandler).Methods("POST")
-	router.HandleFunc("/users/{id:[0-9]+}", DeleteUserHandler).Methods("DELETE")
+
+	// /events is a long-lived WebSocket stream: gorilla/websocket hijacks the
+	// underlying net.Conn, and TimeoutMiddleware sets an absolute read/write
+	// deadline on that same conn once, before the handler runs. Applying it
+	// here would time out the read pump (events/handler.go) a few seconds
+	// into every connection regardless of client activity, so /events is
+	// registered directly on router rather than on the timed subrouter below.
+	// It carries its token in the query string rather than an Authorization
+	// header, since browser WebSocket clients can't set arbitrary headers on
+	// the handshake request; it authenticates itself rather than sitting
+	// behind the header-only JWT middleware.
+	router.Handle("/events", events.Handler(broker, func(token string) error {
+		_, err := auth.ValidateToken(jwtSecret, token)
+		return err
+	})).Methods("GET")
+
+	// Everything else gets the live-reloadable read/write timeouts.
+	timed := router.PathPrefix("").Subrouter()
+	timed.Use(TimeoutMiddleware(cfgWatcher))
+	timed.HandleFunc("/login", auth.LoginHandler(jwtSecret, userDB)).Methods("POST")
+
+	// Everything under /users requires a valid JWT; /login stays open for
+	// credential exchange. /healthz, /readiness and /metrics live on the
+	// admin listener below so probes keep working while this one drains.
+	api := timed.PathPrefix("").Subrouter()
+	api.Use(auth.JWTAuthMiddleware(jwtSecret))
+	api.HandleFunc("/users", ListUserHandler).Methods("GET")
+	api.HandleFunc("/users/{id:[0-9]+}", GetUserHandler).Methods("GET")
+	api.HandleFunc("/users", CreateUserHandler).Methods("POST")
+	api.Handle("/users/{id:[0-9]+}", auth.RequireRole("admin", http.HandlerFunc(DeleteUserHandler))).Methods("DELETE")
 
 	srv := &http.Server{
-		Addr:         ":8080",
-		BaseContext:  func(_ net.Listener) context.Context { return ctx },
-		ReadTimeout:  time.Second,
-		WriteTimeout: 10 * time.Second,
-		Handler:      router,
+		Addr:        fmt.Sprintf(":%s", cfg.HostPort),
+		BaseContext: func(_ net.Listener) context.Context { return ctx },
+		Handler:     router,
+	}
+
+	adminPort := os.Getenv("ADMIN_PORT")
+	if adminPort == "" {
+		adminPort = "9090"
 	}
+	adminMux := http.NewServeMux()
+	adminMux.HandleFunc("/healthz", lc.Healthz)
+	adminMux.HandleFunc("/readiness", lc.Readiness)
+	adminMux.Handle("/metrics", promhttp.Handler())
+	adminSrv := &http.Server{
+		Addr:    fmt.Sprintf(":%s", adminPort),
+		Handler: adminMux,
+	}
+	go func() {
+		fmt.Println("running admin listener")
+		if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("admin listener error: %s\n", err.Error())
+		}
+	}()
+
 	srvErr := make(chan error, 1)
 	go func() {
 		fmt.Println("running server")
-		srvErr <- http.ListenAndServe(fmt.Sprintf(":%s", port), router)
+		srvErr <- srv.ListenAndServe()
 	}()
+	lc.Set(lifecycle.StateReady)
 
 	// Wait for interruption.
 	select {
@@ -197,6 +451,15 @@ func main() {
 		stop()
 	}
 
+	// Flip readiness to false first so load balancers stop routing new
+	// traffic, then give them ShutdownGrace to notice before we drain.
+	lc.Set(lifecycle.StateDraining)
+	time.Sleep(cfgWatcher.Current().ShutdownGrace)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 	// When Shutdown is called, ListenAndServe immediately returns ErrServerClosed.
-	err = srv.Shutdown(context.Background())
+	err = srv.Shutdown(shutdownCtx)
+	adminSrv.Shutdown(context.Background())
+	lc.Set(lifecycle.StateStopped)
 }
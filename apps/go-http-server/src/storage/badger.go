@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// BadgerStore is an embedded, persistent UserStore backed by BadgerDB. It is
+// intended for single-node homelab deployments that want state to survive a
+// restart without standing up a separate database.
+type BadgerStore struct {
+	db     *badger.DB
+	mtx    sync.Mutex
+	nextID int
+}
+
+// NewBadgerStore opens (or creates) a Badger database at dir.
+func NewBadgerStore(dir string) (*BadgerStore, error) {
+	db, err := badger.Open(badger.DefaultOptions(dir))
+	if err != nil {
+		return nil, fmt.Errorf("storage: open badger: %w", err)
+	}
+
+	s := &BadgerStore{db: db, nextID: 1}
+	if err := s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			id, err := strconv.Atoi(string(it.Item().Key()))
+			if err == nil && id >= s.nextID {
+				s.nextID = id + 1
+			}
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("storage: scan badger: %w", err)
+	}
+
+	return s, nil
+}
+
+// Close releases the underlying Badger database handle.
+func (s *BadgerStore) Close() error {
+	return s.db.Close()
+}
+
+func userKey(id int) []byte {
+	return []byte(strconv.Itoa(id))
+}
+
+func (s *BadgerStore) List(ctx context.Context, ownerID string) (map[int]User, error) {
+	owned := make(map[int]User)
+	err := s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			var user User
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &user)
+			}); err != nil {
+				return err
+			}
+			if user.OwnerID == ownerID {
+				owned[user.ID] = user
+			}
+		}
+		return nil
+	})
+	return owned, err
+}
+
+func (s *BadgerStore) Get(ctx context.Context, ownerID string, id int) (User, error) {
+	var user User
+	err := s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(userKey(id))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			return json.Unmarshal(val, &user)
+		})
+	})
+	if err != nil {
+		return User{}, err
+	}
+	if user.OwnerID != ownerID {
+		return User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *BadgerStore) Create(ctx context.Context, ownerID string, user User) (User, error) {
+	s.mtx.Lock()
+	user.ID = s.nextID
+	s.nextID++
+	s.mtx.Unlock()
+
+	user.OwnerID = ownerID
+	return user, s.put(user)
+}
+
+func (s *BadgerStore) Update(ctx context.Context, ownerID string, user User) (User, error) {
+	existing, err := s.Get(ctx, ownerID, user.ID)
+	if err != nil {
+		return User{}, err
+	}
+	user.OwnerID = existing.OwnerID
+	return user, s.put(user)
+}
+
+func (s *BadgerStore) Delete(ctx context.Context, ownerID string, id int) error {
+	if _, err := s.Get(ctx, ownerID, id); err != nil {
+		return err
+	}
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(userKey(id))
+	})
+}
+
+func (s *BadgerStore) put(user User) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(userKey(user.ID), data)
+	})
+}
+
+// MigrateFromMemory dumps every record held by mem into the Badger store,
+// overwriting any existing entry with the same ID. It is meant to run once
+// on shutdown so deployments moving from STORAGE_BACKEND=memory to
+// STORAGE_BACKEND=badger don't lose in-flight state.
+func (s *BadgerStore) MigrateFromMemory(mem *MemoryStore) error {
+	for id, user := range mem.Snapshot() {
+		if err := s.put(user); err != nil {
+			return fmt.Errorf("storage: migrate user %d: %w", id, err)
+		}
+		s.mtx.Lock()
+		if id >= s.nextID {
+			s.nextID = id + 1
+		}
+		s.mtx.Unlock()
+	}
+	return nil
+}
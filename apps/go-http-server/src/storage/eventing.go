@@ -0,0 +1,57 @@
+package storage
+
+import "context"
+
+// EventPublisher is the subset of events.Broker that EventingStore depends
+// on. It is declared here, rather than importing the events package, so
+// storage has no dependency on how events get delivered.
+type EventPublisher interface {
+	Publish(eventType, requestID string, payload any)
+}
+
+type ctxKey int
+
+const requestIDKey ctxKey = 0
+
+// WithRequestID attaches the originating HTTP request's ID to ctx, so it can
+// be threaded through into any event the mutation emits.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID attached by WithRequestID, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// EventingStore wraps a UserStore and publishes a user.created/user.deleted
+// event through publisher after each successful Create or Delete, correlated
+// with the request ID stashed in ctx by the originating HTTP handler. Update
+// is passed through to the wrapped store without publishing an event.
+type EventingStore struct {
+	UserStore
+	Publisher EventPublisher
+}
+
+// NewEventingStore wraps next so its creates and deletes are published
+// through publisher.
+func NewEventingStore(next UserStore, publisher EventPublisher) *EventingStore {
+	return &EventingStore{UserStore: next, Publisher: publisher}
+}
+
+func (s *EventingStore) Create(ctx context.Context, ownerID string, user User) (User, error) {
+	created, err := s.UserStore.Create(ctx, ownerID, user)
+	if err == nil {
+		s.Publisher.Publish("user.created", RequestIDFromContext(ctx), created)
+	}
+	return created, err
+}
+
+func (s *EventingStore) Delete(ctx context.Context, ownerID string, id int) error {
+	err := s.UserStore.Delete(ctx, ownerID, id)
+	if err == nil {
+		s.Publisher.Publish("user.deleted", RequestIDFromContext(ctx), User{ID: id, OwnerID: ownerID})
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore is the original in-memory UserStore implementation. It is the
+// default backend and the one the Badger migration helper copies from on
+// shutdown.
+type MemoryStore struct {
+	mtx    sync.RWMutex
+	users  map[int]User
+	nextID int
+}
+
+// NewMemoryStore returns an empty MemoryStore ready for use.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:  make(map[int]User),
+		nextID: 1,
+	}
+}
+
+func (s *MemoryStore) List(ctx context.Context, ownerID string) (map[int]User, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	owned := make(map[int]User)
+	for id, user := range s.users {
+		if user.OwnerID == ownerID {
+			owned[id] = user
+		}
+	}
+	return owned, nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, ownerID string, id int) (User, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	user, ok := s.users[id]
+	if !ok || user.OwnerID != ownerID {
+		return User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (s *MemoryStore) Create(ctx context.Context, ownerID string, user User) (User, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	user.ID = s.nextID
+	user.OwnerID = ownerID
+	s.users[user.ID] = user
+	s.nextID++
+	return user, nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, ownerID string, user User) (User, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	existing, ok := s.users[user.ID]
+	if !ok || existing.OwnerID != ownerID {
+		return User{}, ErrNotFound
+	}
+	user.OwnerID = ownerID
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, ownerID string, id int) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.users[id].OwnerID != ownerID {
+		return ErrNotFound
+	}
+	delete(s.users, id)
+	return nil
+}
+
+// Snapshot returns a shallow copy of every record across all owners, for use
+// by the Badger migration helper on shutdown.
+func (s *MemoryStore) Snapshot() map[int]User {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	all := make(map[int]User, len(s.users))
+	for id, user := range s.users {
+		all[id] = user
+	}
+	return all
+}
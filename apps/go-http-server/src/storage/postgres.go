@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore is a UserStore backed by Postgres, for deployments that want
+// a shared, horizontally-scalable store rather than a single-node Badger file.
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresStore connects to Postgres at dsn and ensures the users table
+// exists.
+func NewPostgresStore(ctx context.Context, dsn string) (*PostgresStore, error) {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("storage: connect postgres: %w", err)
+	}
+
+	const schema = `
+		CREATE TABLE IF NOT EXISTS users (
+			id       SERIAL PRIMARY KEY,
+			owner_id TEXT NOT NULL,
+			name     TEXT NOT NULL
+		)`
+	if _, err := pool.Exec(ctx, schema); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("storage: migrate postgres schema: %w", err)
+	}
+
+	return &PostgresStore{pool: pool}, nil
+}
+
+// Close releases the underlying connection pool.
+func (s *PostgresStore) Close() error {
+	s.pool.Close()
+	return nil
+}
+
+func (s *PostgresStore) List(ctx context.Context, ownerID string) (map[int]User, error) {
+	rows, err := s.pool.Query(ctx, `SELECT id, owner_id, name FROM users WHERE owner_id = $1`, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list users: %w", err)
+	}
+	defer rows.Close()
+
+	owned := make(map[int]User)
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.OwnerID, &user.Name); err != nil {
+			return nil, fmt.Errorf("storage: scan user: %w", err)
+		}
+		owned[user.ID] = user
+	}
+	return owned, rows.Err()
+}
+
+func (s *PostgresStore) Get(ctx context.Context, ownerID string, id int) (User, error) {
+	var user User
+	err := s.pool.QueryRow(ctx,
+		`SELECT id, owner_id, name FROM users WHERE id = $1 AND owner_id = $2`, id, ownerID,
+	).Scan(&user.ID, &user.OwnerID, &user.Name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return User{}, ErrNotFound
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("storage: get user: %w", err)
+	}
+	return user, nil
+}
+
+func (s *PostgresStore) Create(ctx context.Context, ownerID string, user User) (User, error) {
+	user.OwnerID = ownerID
+	err := s.pool.QueryRow(ctx,
+		`INSERT INTO users (owner_id, name) VALUES ($1, $2) RETURNING id`, user.OwnerID, user.Name,
+	).Scan(&user.ID)
+	if err != nil {
+		return User{}, fmt.Errorf("storage: create user: %w", err)
+	}
+	return user, nil
+}
+
+func (s *PostgresStore) Update(ctx context.Context, ownerID string, user User) (User, error) {
+	tag, err := s.pool.Exec(ctx,
+		`UPDATE users SET name = $1 WHERE id = $2 AND owner_id = $3`, user.Name, user.ID, ownerID,
+	)
+	if err != nil {
+		return User{}, fmt.Errorf("storage: update user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return User{}, ErrNotFound
+	}
+	user.OwnerID = ownerID
+	return user, nil
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, ownerID string, id int) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM users WHERE id = $1 AND owner_id = $2`, id, ownerID)
+	if err != nil {
+		return fmt.Errorf("storage: delete user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+//go:build integration
+
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+func newTestPostgresStore(t *testing.T) *PostgresStore {
+	t.Helper()
+	ctx := context.Background()
+
+	container, err := postgres.Run(ctx, "postgres:16-alpine",
+		postgres.WithDatabase("homelab"),
+		postgres.WithUsername("homelab"),
+		postgres.WithPassword("homelab"),
+	)
+	if err != nil {
+		t.Fatalf("start postgres container: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := testcontainers.TerminateContainer(container); err != nil {
+			t.Logf("terminate postgres container: %v", err)
+		}
+	})
+
+	dsn, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("connection string: %v", err)
+	}
+
+	store, err := NewPostgresStore(ctx, dsn)
+	if err != nil {
+		t.Fatalf("NewPostgresStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestPostgresStoreCRUD(t *testing.T) {
+	ctx := context.Background()
+	store := newTestPostgresStore(t)
+
+	created, err := store.Create(ctx, "alice", User{Name: "Ada"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.ID == 0 {
+		t.Fatalf("Create() did not assign an ID")
+	}
+
+	got, err := store.Get(ctx, "alice", created.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Name != "Ada" {
+		t.Fatalf("Get() name = %q, want %q", got.Name, "Ada")
+	}
+
+	if _, err := store.Get(ctx, "bob", created.ID); err != ErrNotFound {
+		t.Fatalf("Get() for a different owner error = %v, want ErrNotFound", err)
+	}
+
+	updated, err := store.Update(ctx, "alice", User{ID: created.ID, Name: "Ada Lovelace"})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated.Name != "Ada Lovelace" {
+		t.Fatalf("Update() name = %q, want %q", updated.Name, "Ada Lovelace")
+	}
+
+	if err := store.Delete(ctx, "alice", created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, "alice", created.ID); err != ErrNotFound {
+		t.Fatalf("Get() after delete error = %v, want ErrNotFound", err)
+	}
+}
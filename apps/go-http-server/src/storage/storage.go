@@ -0,0 +1,35 @@
+// Package storage defines the UserStore persistence interface and the
+// concrete backends the server can be configured to use.
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when a lookup does not match an owned record.
+var ErrNotFound = errors.New("storage: user not found")
+
+// User represents a simple user model in our application, scoped to the
+// owner that created it.
+type User struct {
+	ID      int    `json:"id"`
+	OwnerID string `json:"-"`
+	Name    string `json:"name"`
+}
+
+// UserStore is the persistence interface for user records. Every method is
+// scoped by ownerID so callers only ever see or mutate their own records.
+type UserStore interface {
+	List(ctx context.Context, ownerID string) (map[int]User, error)
+	Get(ctx context.Context, ownerID string, id int) (User, error)
+	Create(ctx context.Context, ownerID string, user User) (User, error)
+	Update(ctx context.Context, ownerID string, user User) (User, error)
+	Delete(ctx context.Context, ownerID string, id int) error
+}
+
+// Closer is implemented by stores that hold resources (file handles,
+// connection pools) needing an orderly shutdown.
+type Closer interface {
+	Close() error
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Backend names accepted by the STORAGE_BACKEND env var.
+const (
+	BackendMemory   = "memory"
+	BackendBadger   = "badger"
+	BackendPostgres = "postgres"
+)
+
+// NewStore builds the UserStore named by backend, defaulting to the
+// in-memory backend when it is empty. badgerDir and
+// postgresDSN are only consulted for their respective backends.
+func NewStore(ctx context.Context, backend, badgerDir, postgresDSN string) (UserStore, error) {
+	switch backend {
+	case "", BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendBadger:
+		if badgerDir == "" {
+			badgerDir = "./data/badger"
+		}
+		return NewBadgerStore(badgerDir)
+	case BackendPostgres:
+		if postgresDSN == "" {
+			return nil, fmt.Errorf("storage: STORAGE_BACKEND=postgres requires POSTGRES_DSN")
+		}
+		return NewPostgresStore(ctx, postgresDSN)
+	default:
+		return nil, fmt.Errorf("storage: unknown STORAGE_BACKEND %q", backend)
+	}
+}
+
+// NewStoreFromEnv reads STORAGE_BACKEND, BADGER_DIR and POSTGRES_DSN from the
+// environment and delegates to NewStore.
+func NewStoreFromEnv(ctx context.Context) (UserStore, error) {
+	return NewStore(ctx, os.Getenv("STORAGE_BACKEND"), os.Getenv("BADGER_DIR"), os.Getenv("POSTGRES_DSN"))
+}